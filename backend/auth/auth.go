@@ -0,0 +1,84 @@
+// Package auth validates API keys, enforces per-key scopes, and
+// rate-limits requests per key per endpoint class.
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scope is a permission an API key can hold. ScopeAdmin implicitly grants
+// every other scope.
+type Scope string
+
+const (
+	ScopeGenerate Scope = "generate"
+	ScopeExecute  Scope = "execute"
+	ScopeAdmin    Scope = "admin"
+)
+
+// Key is a single API key and what it's allowed to do.
+type Key struct {
+	Token  string
+	Owner  string
+	Scopes map[Scope]bool
+}
+
+// HasScope reports whether k may perform an action requiring scope. An
+// empty scope means "any authenticated key", which every key satisfies.
+func (k Key) HasScope(scope Scope) bool {
+	if scope == "" {
+		return true
+	}
+	return k.Scopes[scope] || k.Scopes[ScopeAdmin]
+}
+
+// Store looks up keys loaded from API_KEYS.
+type Store struct {
+	keys map[string]Key
+}
+
+// NewStore parses raw in the API_KEYS format - comma-separated
+// "token:owner:scope[+scope...]" entries, e.g.
+// "key1:alice:execute,key2:bob:generate+execute" - into a Store. An empty
+// raw string yields a Store with no keys, which rejects every request;
+// that's deliberate, since the alternative is silently running wide open.
+func NewStore(raw string) (*Store, error) {
+	keys := map[string]Key{}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return &Store{keys: keys}, nil
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid API key entry %q, expected token:owner:scopes", entry)
+		}
+		token, owner, scopesField := parts[0], parts[1], parts[2]
+		if token == "" || owner == "" || scopesField == "" {
+			return nil, fmt.Errorf("invalid API key entry %q, expected token:owner:scopes", entry)
+		}
+
+		scopes := map[Scope]bool{}
+		for _, s := range strings.Split(scopesField, "+") {
+			scopes[Scope(s)] = true
+		}
+
+		keys[token] = Key{Token: token, Owner: owner, Scopes: scopes}
+	}
+
+	return &Store{keys: keys}, nil
+}
+
+// Lookup returns the key for token, if any.
+func (s *Store) Lookup(token string) (Key, bool) {
+	k, ok := s.keys[token]
+	return k, ok
+}
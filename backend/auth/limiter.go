@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// Limiters holds one token-bucket per (endpoint class, API key) pair, so a
+// burst against one endpoint class (say /execute-script) can't starve a
+// key's budget for another (/generate-script).
+type Limiters struct {
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter
+	perSec  rate.Limit
+	burst   int
+}
+
+// NewLimiters returns a Limiters that allows perSec requests/sec per
+// (class, key) pair, with bursts up to burst.
+func NewLimiters(perSec rate.Limit, burst int) *Limiters {
+	return &Limiters{
+		buckets: map[string]*rate.Limiter{},
+		perSec:  perSec,
+		burst:   burst,
+	}
+}
+
+// Allow reports whether a request against class, authenticated as token,
+// is within its rate limit, consuming from the bucket if so.
+func (l *Limiters) Allow(class, token string) bool {
+	return l.bucket(class, token).Allow()
+}
+
+func (l *Limiters) bucket(class, token string) *rate.Limiter {
+	key := class + ":" + token
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lim, ok := l.buckets[key]
+	if !ok {
+		lim = rate.NewLimiter(l.perSec, l.burst)
+		l.buckets[key] = lim
+	}
+	return lim
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Middleware returns a Gin middleware that requires a valid API key,
+// enforces that it carries scope (pass "" to only require authentication),
+// and rate-limits the request under class using limiters. Every decision is
+// audit-logged, keyed by a request ID generated for the request.
+func Middleware(store *Store, limiters *Limiters, class string, scope Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := uuid.NewString()
+		c.Set("request_id", requestID)
+
+		token := extractToken(c)
+		if token == "" {
+			audit(requestID, "", class, "missing API key")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing API key", "request_id": requestID})
+			return
+		}
+
+		key, ok := store.Lookup(token)
+		if !ok {
+			audit(requestID, "", class, "unknown API key")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key", "request_id": requestID})
+			return
+		}
+
+		if !key.HasScope(scope) {
+			audit(requestID, key.Owner, class, "missing scope "+string(scope))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks required scope", "request_id": requestID})
+			return
+		}
+
+		if !limiters.Allow(class, token) {
+			audit(requestID, key.Owner, class, "rate limited")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded", "request_id": requestID})
+			return
+		}
+
+		audit(requestID, key.Owner, class, "allowed")
+		c.Set("auth_owner", key.Owner)
+		c.Next()
+	}
+}
+
+// extractToken reads the API key from X-API-Key, or failing that, from a
+// "Bearer <token>" Authorization header.
+func extractToken(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return ""
+}
+
+func audit(requestID, owner, class, decision string) {
+	log.Printf("auth request_id=%s owner=%q endpoint_class=%s decision=%q", requestID, owner, class, decision)
+}
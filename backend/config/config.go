@@ -0,0 +1,57 @@
+// Package config loads the server's provider settings from a YAML file,
+// so model/temperature/etc. can be tuned without rebuilding the binary.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProviderConfig holds the per-provider settings read from the config file.
+type ProviderConfig struct {
+	Model       string  `yaml:"model"`
+	Temperature float64 `yaml:"temperature"`
+	TopP        float64 `yaml:"top_p"`
+	MaxTokens   int     `yaml:"max_tokens"`
+	Host        string  `yaml:"host"` // used by ollama
+}
+
+// Config is the top-level shape of config.yaml.
+type Config struct {
+	Provider  string                    `yaml:"provider"`
+	Providers map[string]ProviderConfig `yaml:"providers"`
+}
+
+// Default returns the configuration used when no config file is present.
+func Default() *Config {
+	return &Config{
+		Provider: "anthropic",
+		Providers: map[string]ProviderConfig{
+			"anthropic": {Model: "claude-3-opus-20240229", MaxTokens: 4096},
+			"gemini":    {Model: "gemini-1.5-flash-latest", Temperature: 0.7, TopP: 0.95, MaxTokens: 2048},
+			"openai":    {Model: "gpt-4o-mini", Temperature: 0.7, MaxTokens: 4096},
+			"ollama":    {Model: "codellama", Host: "http://localhost:11434"},
+		},
+	}
+}
+
+// Load reads and parses the YAML config at path. If path doesn't exist,
+// it returns Default() rather than an error, since a config file is
+// optional.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+
+	cfg := Default()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+	return cfg, nil
+}
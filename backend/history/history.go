@@ -0,0 +1,208 @@
+// Package history persists generated scripts and their executions to
+// SQLite, so past prompts/scripts can be listed, re-run, and refined
+// instead of being lost the moment the response is sent.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound is returned (wrapped) by GetScript when no script with the
+// given ID exists.
+var ErrNotFound = errors.New("not found")
+
+// Script is one /generate-script (or /refine) result.
+type Script struct {
+	ID        int64
+	CreatedAt time.Time
+	Prompt    string
+	Provider  string
+	Model     string
+	Script    string
+	ParentID  *int64
+}
+
+// Execution is one /execute-script run against a stored Script.
+type Execution struct {
+	ID            int64     `json:"id"`
+	ScriptID      int64     `json:"script_id"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	ExitCode      int       `json:"exit_code"`
+	Stdout        string    `json:"stdout"`
+	Stderr        string    `json:"stderr"`
+	SandboxConfig string    `json:"sandbox_config"` // JSON-encoded sandbox.Config, for audit/repro
+}
+
+// Store wraps a SQLite-backed history database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the SQLite database at path and
+// ensures its schema is up to date.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	// modernc.org/sqlite doesn't support concurrent writers well; the
+	// server's write volume is low enough that serializing through a
+	// single connection is simpler than adding a write-lock of our own.
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) Close() error { return s.db.Close() }
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS scripts (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			created_at TIMESTAMP NOT NULL,
+			prompt     TEXT NOT NULL,
+			provider   TEXT NOT NULL,
+			model      TEXT NOT NULL,
+			script     TEXT NOT NULL,
+			parent_id  INTEGER REFERENCES scripts(id)
+		);
+
+		CREATE TABLE IF NOT EXISTS executions (
+			id             INTEGER PRIMARY KEY AUTOINCREMENT,
+			script_id      INTEGER NOT NULL REFERENCES scripts(id),
+			started_at     TIMESTAMP NOT NULL,
+			finished_at    TIMESTAMP NOT NULL,
+			exit_code      INTEGER NOT NULL,
+			stdout         TEXT NOT NULL,
+			stderr         TEXT NOT NULL,
+			sandbox_config TEXT NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate history database: %w", err)
+	}
+	return nil
+}
+
+// SaveScript inserts s and returns its assigned ID.
+func (s *Store) SaveScript(ctx context.Context, sc Script) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO scripts (created_at, prompt, provider, model, script, parent_id) VALUES (?, ?, ?, ?, ?, ?)`,
+		sc.CreatedAt, sc.Prompt, sc.Provider, sc.Model, sc.Script, sc.ParentID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save script: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// GetScript fetches a script by ID.
+func (s *Store) GetScript(ctx context.Context, id int64) (Script, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, created_at, prompt, provider, model, script, parent_id FROM scripts WHERE id = ?`, id)
+
+	var sc Script
+	if err := row.Scan(&sc.ID, &sc.CreatedAt, &sc.Prompt, &sc.Provider, &sc.Model, &sc.Script, &sc.ParentID); err != nil {
+		if err == sql.ErrNoRows {
+			return Script{}, fmt.Errorf("script %d: %w", id, ErrNotFound)
+		}
+		return Script{}, fmt.Errorf("failed to load script %d: %w", id, err)
+	}
+	return sc, nil
+}
+
+// ListParams controls pagination and filtering for ListScripts.
+type ListParams struct {
+	Limit  int
+	Offset int
+	// Query, if set, restricts results to scripts whose prompt or script
+	// text contains it (case-insensitive substring match).
+	Query string
+}
+
+// ListScripts returns a page of scripts (most recent first) matching
+// params, plus the total number of matches across all pages.
+func (s *Store) ListScripts(ctx context.Context, params ListParams) ([]Script, int, error) {
+	limit := params.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	where := ""
+	args := []any{}
+	if params.Query != "" {
+		where = "WHERE prompt LIKE ? OR script LIKE ?"
+		like := "%" + params.Query + "%"
+		args = append(args, like, like)
+	}
+
+	var total int
+	countRow := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM scripts "+where, args...)
+	if err := countRow.Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count scripts: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, created_at, prompt, provider, model, script, parent_id FROM scripts "+where+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list scripts: %w", err)
+	}
+	defer rows.Close()
+
+	var scripts []Script
+	for rows.Next() {
+		var sc Script
+		if err := rows.Scan(&sc.ID, &sc.CreatedAt, &sc.Prompt, &sc.Provider, &sc.Model, &sc.Script, &sc.ParentID); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan script row: %w", err)
+		}
+		scripts = append(scripts, sc)
+	}
+	return scripts, total, rows.Err()
+}
+
+// SaveExecution inserts e and returns its assigned ID.
+func (s *Store) SaveExecution(ctx context.Context, e Execution) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO executions (script_id, started_at, finished_at, exit_code, stdout, stderr, sandbox_config) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.ScriptID, e.StartedAt, e.FinishedAt, e.ExitCode, e.Stdout, e.Stderr, e.SandboxConfig,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save execution: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// ListExecutions returns every execution recorded against scriptID, most
+// recent first.
+func (s *Store) ListExecutions(ctx context.Context, scriptID int64) ([]Execution, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, script_id, started_at, finished_at, exit_code, stdout, stderr, sandbox_config
+		 FROM executions WHERE script_id = ? ORDER BY id DESC`, scriptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list executions for script %d: %w", scriptID, err)
+	}
+	defer rows.Close()
+
+	var executions []Execution
+	for rows.Next() {
+		var e Execution
+		if err := rows.Scan(&e.ID, &e.ScriptID, &e.StartedAt, &e.FinishedAt, &e.ExitCode, &e.Stdout, &e.Stderr, &e.SandboxConfig); err != nil {
+			return nil, fmt.Errorf("failed to scan execution row: %w", err)
+		}
+		executions = append(executions, e)
+	}
+	return executions, rows.Err()
+}
@@ -0,0 +1,233 @@
+// Package lint implements a small rule engine that flags shell scripts for
+// common safety problems before they're executed, plus an optional pass
+// through shellcheck when it's available on PATH.
+package lint
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Severity classifies how serious a Finding is. Error-level findings are
+// expected to block execution unless the caller explicitly overrides them.
+type Severity string
+
+const (
+	SeverityInfo  Severity = "info"
+	SeverityWarn  Severity = "warn"
+	SeverityError Severity = "error"
+)
+
+// Finding is a single issue reported against a line of the script.
+type Finding struct {
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+}
+
+type rule struct {
+	id       string
+	severity Severity
+	message  string
+	pattern  *regexp.Regexp
+}
+
+// lineRules are checked against the raw text of each line. They catch the
+// same broad strokes as backend's own destructivePatterns check, plus a
+// few more that are only worth flagging rather than hard-blocking.
+var lineRules = []rule{
+	{
+		id:       "fork-bomb",
+		severity: SeverityError,
+		message:  "classic fork bomb pattern",
+		pattern:  regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`),
+	},
+	{
+		id:       "curl-pipe-bash",
+		severity: SeverityError,
+		message:  "pipes a remote download straight into a shell",
+		pattern:  regexp.MustCompile(`(curl|wget)[^|]*\|\s*(sudo\s+)?(bash|sh)\b`),
+	},
+	{
+		id:       "dd-raw-disk",
+		severity: SeverityError,
+		message:  "dd writing directly to a block device",
+		pattern:  regexp.MustCompile(`\bdd\b.*\bof=/dev/sd[a-z]*\b`),
+	},
+	{
+		id:       "mkfs",
+		severity: SeverityError,
+		message:  "formats a filesystem",
+		pattern:  regexp.MustCompile(`\bmkfs(\.\w+)?\b`),
+	},
+	{
+		id:       "chmod-777-root",
+		severity: SeverityError,
+		message:  "makes the root filesystem world-writable",
+		pattern:  regexp.MustCompile(`\bchmod\s+(-R\s+)?777\s+/(\s|$)`),
+	},
+	{
+		id:       "eval-on-expansion",
+		severity: SeverityWarn,
+		message:  "eval over an unvalidated variable or command substitution",
+		pattern:  regexp.MustCompile(`\beval\b[^\n]*\$`),
+	},
+}
+
+// Analyze runs every rule over script and returns the findings in line
+// order. allowedWriteDirs restricts where redirection/tee targets may
+// write without triggering the "write-outside-allowlist" rule; a nil or
+// empty slice disables that check.
+func Analyze(script string, allowedWriteDirs []string) []Finding {
+	var findings []Finding
+
+	for i, line := range strings.Split(script, "\n") {
+		lineNo := i + 1
+
+		for _, r := range lineRules {
+			if r.pattern.MatchString(line) {
+				findings = append(findings, Finding{
+					RuleID:   r.id,
+					Severity: r.severity,
+					Line:     lineNo,
+					Message:  r.message,
+				})
+			}
+		}
+
+		if f, ok := checkRmRf(line, lineNo); ok {
+			findings = append(findings, f)
+		}
+
+		if len(allowedWriteDirs) > 0 {
+			findings = append(findings, checkWriteTargets(line, lineNo, allowedWriteDirs)...)
+		}
+	}
+
+	return findings
+}
+
+// checkRmRf flags `rm -rf` invocations whose target is an unquoted
+// variable (which could expand to empty or "/") or a path that's
+// obviously the filesystem root.
+func checkRmRf(line string, lineNo int) (Finding, bool) {
+	tokens := tokenize(line)
+	if len(tokens) == 0 || tokens[0] != "rm" {
+		return Finding{}, false
+	}
+
+	hasRecursiveForce := false
+	for _, t := range tokens[1:] {
+		if !strings.HasPrefix(t, "-") {
+			continue
+		}
+		if strings.Contains(t, "r") && strings.Contains(t, "f") {
+			hasRecursiveForce = true
+		}
+	}
+	if !hasRecursiveForce {
+		return Finding{}, false
+	}
+
+	for _, t := range tokens[1:] {
+		if strings.HasPrefix(t, "-") {
+			continue
+		}
+		if t == "/" || t == "/*" {
+			return Finding{
+				RuleID:   "rm-rf-root",
+				Severity: SeverityError,
+				Line:     lineNo,
+				Message:  "rm -rf targets the filesystem root",
+			}, true
+		}
+		if strings.HasPrefix(t, "$") && !strings.HasPrefix(t, `"`) {
+			return Finding{
+				RuleID:   "rm-rf-unquoted-var",
+				Severity: SeverityError,
+				Line:     lineNo,
+				Message:  "rm -rf on an unquoted variable can delete unintended paths if it expands empty or to /",
+			}, true
+		}
+	}
+
+	return Finding{}, false
+}
+
+// checkWriteTargets flags redirections and tee invocations that write
+// outside allowedDirs.
+func checkWriteTargets(line string, lineNo int, allowedDirs []string) []Finding {
+	var findings []Finding
+
+	for _, target := range writeTargets(line) {
+		if target == "" || strings.HasPrefix(target, "&") {
+			continue // e.g. `2>&1`, not a filesystem path
+		}
+		if isAllowedPath(target, allowedDirs) {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:   "write-outside-allowlist",
+			Severity: SeverityWarn,
+			Line:     lineNo,
+			Message:  "writes to \"" + target + "\", which is outside the configured allowlist",
+		})
+	}
+
+	return findings
+}
+
+var redirectPattern = regexp.MustCompile(`(?:^|\s)(?:[0-9]*>>?|tee\s+(?:-a\s+)?)\s*("?[^\s"]+"?)`)
+
+func writeTargets(line string) []string {
+	var targets []string
+	for _, m := range redirectPattern.FindAllStringSubmatch(line, -1) {
+		targets = append(targets, strings.Trim(m[1], `"'`))
+	}
+	return targets
+}
+
+func isAllowedPath(path string, allowedDirs []string) bool {
+	for _, dir := range allowedDirs {
+		if strings.HasPrefix(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits a line into whitespace-separated tokens, treating
+// anything inside single or double quotes as part of the same token so
+// that e.g. `rm -rf "$VAR"` isn't mistaken for an unquoted expansion.
+func tokenize(line string) []string {
+	var tokens []string
+	var cur strings.Builder
+	var inSingle, inDouble bool
+
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range line {
+		switch {
+		case r == '\'' && !inDouble:
+			inSingle = !inSingle
+			cur.WriteRune(r)
+		case r == '"' && !inSingle:
+			inDouble = !inDouble
+			cur.WriteRune(r)
+		case unicode.IsSpace(r) && !inSingle && !inDouble:
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
@@ -0,0 +1,47 @@
+package lint
+
+import "testing"
+
+func hasRule(findings []Finding, ruleID string) bool {
+	for _, f := range findings {
+		if f.RuleID == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAnalyzeFlagsRmRfUnquotedVar(t *testing.T) {
+	findings := Analyze("rm -rf $TARGET_DIR", nil)
+	if !hasRule(findings, "rm-rf-unquoted-var") {
+		t.Fatalf("expected rm-rf-unquoted-var finding, got %+v", findings)
+	}
+}
+
+func TestAnalyzeAllowsQuotedRmRf(t *testing.T) {
+	findings := Analyze(`rm -rf "$TARGET_DIR"`, nil)
+	if hasRule(findings, "rm-rf-unquoted-var") {
+		t.Fatalf("did not expect rm-rf-unquoted-var finding, got %+v", findings)
+	}
+}
+
+func TestAnalyzeFlagsCurlPipeBash(t *testing.T) {
+	findings := Analyze("curl https://example.com/install.sh | bash", nil)
+	if !hasRule(findings, "curl-pipe-bash") {
+		t.Fatalf("expected curl-pipe-bash finding, got %+v", findings)
+	}
+}
+
+func TestAnalyzeFlagsWriteOutsideAllowlist(t *testing.T) {
+	findings := Analyze("echo hi > /etc/passwd", []string{"/tmp"})
+	if !hasRule(findings, "write-outside-allowlist") {
+		t.Fatalf("expected write-outside-allowlist finding, got %+v", findings)
+	}
+}
+
+func TestAnalyzeAllowsWriteInsideAllowlist(t *testing.T) {
+	findings := Analyze("echo hi > /tmp/out.txt", []string{"/tmp"})
+	if hasRule(findings, "write-outside-allowlist") {
+		t.Fatalf("did not expect write-outside-allowlist finding, got %+v", findings)
+	}
+}
@@ -0,0 +1,61 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+type shellcheckComment struct {
+	Line    int    `json:"line"`
+	Level   string `json:"level"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// RunShellcheck runs shellcheck over script and returns its findings. If
+// shellcheck isn't installed, it returns (nil, nil) rather than an error -
+// the built-in rule engine in Analyze still runs regardless.
+func RunShellcheck(script string) ([]Finding, error) {
+	path, err := exec.LookPath("shellcheck")
+	if err != nil {
+		return nil, nil
+	}
+
+	cmd := exec.Command(path, "-f", "json", "-")
+	cmd.Stdin = bytes.NewBufferString(script)
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	// shellcheck exits non-zero when it has findings, which isn't an error
+	// for our purposes - only a failure to run it at all is.
+	_ = cmd.Run()
+
+	var comments []shellcheckComment
+	if err := json.Unmarshal(stdout.Bytes(), &comments); err != nil {
+		return nil, fmt.Errorf("failed to parse shellcheck output: %w", err)
+	}
+
+	findings := make([]Finding, 0, len(comments))
+	for _, c := range comments {
+		findings = append(findings, Finding{
+			RuleID:   fmt.Sprintf("SC%d", c.Code),
+			Severity: shellcheckSeverity(c.Level),
+			Line:     c.Line,
+			Message:  c.Message,
+		})
+	}
+	return findings, nil
+}
+
+func shellcheckSeverity(level string) Severity {
+	switch level {
+	case "error":
+		return SeverityError
+	case "warning":
+		return SeverityWarn
+	default:
+		return SeverityInfo
+	}
+}
@@ -1,80 +1,283 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
-	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"script-copilot/backend/auth"
+	"script-copilot/backend/config"
+	"script-copilot/backend/history"
+	"script-copilot/backend/lint"
+	"script-copilot/backend/provider"
+	"script-copilot/backend/sandbox"
 )
 
 type ScriptRequest struct {
 	Prompt string `json:"prompt" binding:"required"`
+	// Provider overrides the server's default provider for this request
+	// ("anthropic", "gemini", "openai", "ollama").
+	Provider string `json:"provider,omitempty"`
 }
 
 type ScriptResponse struct {
+	ID     int64  `json:"id"`
 	Script string `json:"script"`
 }
 
 type ExecuteScriptRequest struct {
+	Script         string   `json:"script" binding:"required"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+	MemoryMB       int      `json:"memory_mb,omitempty"`
+	AllowNetwork   bool     `json:"allow_network,omitempty"`
+	Env            []string `json:"env,omitempty"`
+	// Confirm must be true for scripts matching a destructive pattern
+	// (see destructivePatterns) to run at all.
+	Confirm bool `json:"confirm,omitempty"`
+	// Override must be true, with OverrideReason set, to run a script that the
+	// lint pass (see runLint) flagged with an error-level finding.
+	Override       bool   `json:"override,omitempty"`
+	OverrideReason string `json:"override_reason,omitempty"`
+	// ScriptID associates this run with a previously generated script (see
+	// POST /scripts/:id/rerun), so the execution is recorded against it in
+	// history instead of under a new ad-hoc script row.
+	ScriptID *int64 `json:"script_id,omitempty"`
+}
+
+// RefineScriptRequest asks the LLM to revise a previously generated script
+// per new instructions, keeping the original around as its parent.
+type RefineScriptRequest struct {
+	Prompt string `json:"prompt" binding:"required"`
+	// Provider overrides the parent script's provider for this refinement.
+	Provider string `json:"provider,omitempty"`
+}
+
+// RerunScriptRequest re-executes a previously generated script, optionally
+// overriding its sandbox limits.
+type RerunScriptRequest struct {
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+	MemoryMB       int      `json:"memory_mb,omitempty"`
+	AllowNetwork   bool     `json:"allow_network,omitempty"`
+	Env            []string `json:"env,omitempty"`
+}
+
+type AnalyzeScriptRequest struct {
 	Script string `json:"script" binding:"required"`
 }
 
+type AnalyzeScriptResponse struct {
+	Findings []lint.Finding `json:"findings"`
+}
+
+// allowedWriteDirs restricts where a script may write without tripping the
+// "write-outside-allowlist" lint rule. Configurable via ANALYZE_ALLOWED_DIRS
+// (comma-separated) since deployments differ in what scratch space they
+// want to offer scripts.
+func allowedWriteDirs() []string {
+	if raw := os.Getenv("ANALYZE_ALLOWED_DIRS"); raw != "" {
+		return strings.Split(raw, ",")
+	}
+	return []string{"/tmp"}
+}
+
+// runLint runs the built-in rule engine plus shellcheck (if installed) over
+// script, returning every finding from both.
+func runLint(script string) []lint.Finding {
+	findings := lint.Analyze(script, allowedWriteDirs())
+
+	scFindings, err := lint.RunShellcheck(script)
+	if err != nil {
+		log.Printf("shellcheck failed, continuing with built-in findings only: %v", err)
+	}
+	return append(findings, scFindings...)
+}
+
 type ExecuteScriptResponse struct {
-	Success bool   `json:"success"`
-	Output  string `json:"output,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success      bool   `json:"success"`
+	Output       string `json:"output,omitempty"`
+	Error        string `json:"error,omitempty"`
+	ExitCode     int    `json:"exit_code"`
+	TimedOut     bool   `json:"timed_out"`
+	Killed       bool   `json:"killed"`
+	PeakMemoryKB int64  `json:"peak_memory_kb,omitempty"`
 }
 
-type AnthropicRequest struct {
-	Model     string    `json:"model"`
-	Messages  []Message `json:"messages"`
-	System    string    `json:"system"`
-	MaxTokens int       `json:"max_tokens"`
+// destructivePatterns catches the most common ways a generated script can
+// wreck the host it runs on. This is a coarse pre-filter, not a substitute
+// for real sandboxing - it only gates whether Confirm is required.
+var destructivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`rm\s+-rf\s+/(\s|$)`),
+	regexp.MustCompile(`rm\s+-rf\s+[^|&;]*\$`),
+	regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`),
+	regexp.MustCompile(`curl[^|]*\|\s*(sudo\s+)?bash`),
+	regexp.MustCompile(`wget[^|]*\|\s*(sudo\s+)?bash`),
+	regexp.MustCompile(`dd\s+.*of=/dev/sd`),
+	regexp.MustCompile(`mkfs\.`),
+	regexp.MustCompile(`chmod\s+777\s+/(\s|$)`),
 }
 
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+func findDestructivePattern(script string) string {
+	for _, re := range destructivePatterns {
+		if re.MatchString(script) {
+			return re.String()
+		}
+	}
+	return ""
+}
+
+const scriptSystemPrompt = `You are an expert in writing shell scripts for Linux systems.
+					Generate practical, secure, and efficient shell scripts based on the user's requirements.
+					Include helpful comments explaining what the script does.
+					Always include proper error handling and input validation where appropriate.`
+
+// buildRegistry wires up every provider, taking API keys from the
+// environment and using cfg for model/temperature/etc. defaults. Providers
+// without an API key are still registered - they'll simply fail at request
+// time if selected, which is more useful for debugging than silently
+// omitting them from PROVIDER/"provider" selection. Ollama needs no key and
+// falls back to http://localhost:11434 when cfg sets no host.
+func buildRegistry(cfg *config.Config) (*provider.Registry, error) {
+	providers := map[string]provider.Provider{
+		"anthropic": &provider.AnthropicProvider{
+			APIKey:      os.Getenv("ANTHROPIC_API_KEY"),
+			Model:       cfg.Providers["anthropic"].Model,
+			Temperature: cfg.Providers["anthropic"].Temperature,
+			TopP:        cfg.Providers["anthropic"].TopP,
+			MaxTokens:   cfg.Providers["anthropic"].MaxTokens,
+		},
+		"gemini": &provider.GeminiProvider{
+			APIKey:      os.Getenv("GEMINI_API_KEY"),
+			Model:       cfg.Providers["gemini"].Model,
+			Temperature: cfg.Providers["gemini"].Temperature,
+			TopP:        cfg.Providers["gemini"].TopP,
+			MaxTokens:   cfg.Providers["gemini"].MaxTokens,
+		},
+		"openai": &provider.OpenAIProvider{
+			APIKey:      os.Getenv("OPENAI_API_KEY"),
+			Model:       cfg.Providers["openai"].Model,
+			Temperature: cfg.Providers["openai"].Temperature,
+			MaxTokens:   cfg.Providers["openai"].MaxTokens,
+		},
+		"ollama": &provider.OllamaProvider{
+			Host:  cfg.Providers["ollama"].Host,
+			Model: cfg.Providers["ollama"].Model,
+		},
+	}
+
+	defaultProvider := os.Getenv("PROVIDER")
+	if defaultProvider == "" {
+		defaultProvider = cfg.Provider
+	}
+
+	return provider.NewRegistry(defaultProvider, providers)
 }
 
-type AnthropicResponse struct {
-	Id      string `json:"id"`
-	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"content"`
-	Model string `json:"model"`
-	Role  string `json:"role"`
+// buildAuth loads API keys from API_KEYS ("token:owner:scope[+scope...],...")
+// and sets up the per-key, per-endpoint-class rate limiters that sit behind
+// them. Rate limits default to 1 req/s with a burst of 5 per key per class,
+// tunable via AUTH_RATE_PER_SECOND / AUTH_BURST since that's reasonable for
+// a single caller but would be far too tight for a shared integration key.
+func buildAuth() (*auth.Store, *auth.Limiters, error) {
+	store, err := auth.NewStore(os.Getenv("API_KEYS"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rps := 1.0
+	if raw := os.Getenv("AUTH_RATE_PER_SECOND"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid AUTH_RATE_PER_SECOND %q: %w", raw, err)
+		}
+		rps = parsed
+	}
+
+	burst := 5
+	if raw := os.Getenv("AUTH_BURST"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid AUTH_BURST %q: %w", raw, err)
+		}
+		burst = parsed
+	}
+
+	return store, auth.NewLimiters(rate.Limit(rps), burst), nil
 }
 
 func main() {
-	// Get Anthropic API key from environment variable
-	apiKey := os.Getenv("OPENAI_API_KEY") // keeping the same env var name for convenience
-	if apiKey == "" {
-		log.Fatal("OPENAI_API_KEY environment variable is not set")
+	cfg, err := config.Load("config.yaml")
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	registry, err := buildRegistry(cfg)
+	if err != nil {
+		log.Fatalf("failed to set up providers: %v", err)
+	}
+
+	historyDBPath := os.Getenv("HISTORY_DB_PATH")
+	if historyDBPath == "" {
+		historyDBPath = "script_history.db"
+	}
+	store, err := history.Open(historyDBPath)
+	if err != nil {
+		log.Fatalf("failed to open script history database: %v", err)
+	}
+	defer store.Close()
+
+	authStore, limiters, err := buildAuth()
+	if err != nil {
+		log.Fatalf("failed to set up auth: %v", err)
+	}
+	if os.Getenv("API_KEYS") == "" {
+		log.Println("WARNING: API_KEYS is not set; every request will be rejected until it is")
 	}
 
 	r := gin.Default()
 
 	// Configure CORS
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:3000"}
-	config.AllowMethods = []string{"POST", "GET", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type"}
-	r.Use(cors.New(config))
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = []string{"http://localhost:3000"}
+	corsConfig.AllowMethods = []string{"POST", "GET", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "X-API-Key"}
+	r.Use(cors.New(corsConfig))
+
+	generateAuth := auth.Middleware(authStore, limiters, "generate", auth.ScopeGenerate)
+	executeAuth := auth.Middleware(authStore, limiters, "execute", auth.ScopeExecute)
+	historyAuth := auth.Middleware(authStore, limiters, "history", "")
 
 	// Existing generate-script endpoint
-	r.POST("/generate-script", handleGenerateScript(apiKey))
+	r.POST("/generate-script", generateAuth, handleGenerateScript(registry, store))
+	r.POST("/generate-script/stream", generateAuth, handleGenerateScriptStream(registry))
 
 	// New execute-script endpoint
-	r.POST("/execute-script", handleExecuteScript())
+	r.POST("/execute-script", executeAuth, handleExecuteScript(store))
+	r.POST("/execute-script/stream", executeAuth, handleExecuteScriptStream(store))
+
+	// Static safety analysis, also run automatically before execution
+	r.POST("/analyze-script", generateAuth, handleAnalyzeScript())
+
+	// Script history: past generations, their executions, and re-running
+	// or refining a past script.
+	r.GET("/scripts", historyAuth, handleListScripts(store))
+	r.GET("/scripts/:id", historyAuth, handleGetScript(store))
+	r.GET("/scripts/:id/executions", historyAuth, handleListExecutions(store))
+	r.POST("/scripts/:id/rerun", executeAuth, handleRerunScript(store))
+	r.POST("/scripts/:id/refine", generateAuth, handleRefineScript(registry, store))
 
 	// Start server
 	log.Println("Server starting on :8080...")
@@ -83,7 +286,7 @@ func main() {
 	}
 }
 
-func handleGenerateScript(apiKey string) gin.HandlerFunc {
+func handleGenerateScript(registry *provider.Registry, store *history.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req ScriptRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -91,109 +294,289 @@ func handleGenerateScript(apiKey string) gin.HandlerFunc {
 			return
 		}
 
-		log.Printf("Received prompt: %s", req.Prompt)
+		log.Printf("Received prompt (provider=%q): %s", req.Provider, req.Prompt)
 
-		// Create the Anthropic API request
-		anthropicReq := AnthropicRequest{
-			Model: "claude-3-opus-20240229",
-			Messages: []Message{
-				{
-					Role:    "user",
-					Content: req.Prompt,
-				},
-			},
-			System: `You are an expert in writing shell scripts for Linux systems. 
-					Generate practical, secure, and efficient shell scripts based on the user's requirements. 
-					Include helpful comments explaining what the script does.
-					Always include proper error handling and input validation where appropriate.`,
-			MaxTokens: 4096,
+		p, err := registry.Get(req.Provider)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
-		jsonData, err := json.Marshal(anthropicReq)
+		scriptContent, err := p.Generate(c.Request.Context(), scriptSystemPrompt, req.Prompt, provider.Opts{})
 		if err != nil {
-			log.Printf("Error marshaling request: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
+			log.Printf("Error generating script with %s: %v", p.Name(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		log.Printf("Request body: %s", string(jsonData))
-
-		// Create HTTP request to Anthropic API
-		httpReq, err := http.NewRequest("POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+		id, err := store.SaveScript(c.Request.Context(), history.Script{
+			CreatedAt: time.Now(),
+			Prompt:    req.Prompt,
+			Provider:  p.Name(),
+			Model:     p.ResolvedModel(provider.Opts{}),
+			Script:    scriptContent,
+		})
 		if err != nil {
-			log.Printf("Error creating request: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
-			return
+			log.Printf("Error saving script to history: %v", err)
 		}
 
-		// Set headers
-		httpReq.Header.Set("Content-Type", "application/json")
-		httpReq.Header.Set("x-api-key", apiKey)
-		httpReq.Header.Set("anthropic-version", "2023-06-01")
+		c.JSON(http.StatusOK, ScriptResponse{
+			ID:     id,
+			Script: scriptContent,
+		})
+	}
+}
 
-		// Send request
-		client := &http.Client{}
-		resp, err := client.Do(httpReq)
-		if err != nil {
-			log.Printf("Error sending request: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send request to AI"})
+func handleAnalyzeScript() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AnalyzeScriptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		defer resp.Body.Close()
 
-		// Read response
-		body, err := ioutil.ReadAll(resp.Body)
+		c.JSON(http.StatusOK, AnalyzeScriptResponse{Findings: runLint(req.Script)})
+	}
+}
+
+// blockingLintFindings returns the error-level findings from runLint, or
+// nil if req.Override is set together with a non-empty req.OverrideReason
+// (in which case it logs how many findings were overridden and why, for
+// audit purposes).
+func blockingLintFindings(script string, req ExecuteScriptRequest) []lint.Finding {
+	var errors []lint.Finding
+	for _, f := range runLint(script) {
+		if f.Severity == lint.SeverityError {
+			errors = append(errors, f)
+		}
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	if req.Override && req.OverrideReason != "" {
+		log.Printf("Overriding %d error-level lint finding(s) to run script, reason: %s", len(errors), req.OverrideReason)
+		return nil
+	}
+	return errors
+}
+
+// writeScriptTempFile writes script to a new executable temp file and
+// returns its path. The caller is responsible for removing it.
+func writeScriptTempFile(script string) (string, error) {
+	tmpfile, err := ioutil.TempFile("", "script-*.sh")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary script file: %w", err)
+	}
+
+	if _, err := tmpfile.Write([]byte(script)); err != nil {
+		tmpfile.Close()
+		os.Remove(tmpfile.Name())
+		return "", fmt.Errorf("failed to write script to file: %w", err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		os.Remove(tmpfile.Name())
+		return "", fmt.Errorf("failed to close script file: %w", err)
+	}
+	if err := os.Chmod(tmpfile.Name(), 0755); err != nil {
+		os.Remove(tmpfile.Name())
+		return "", fmt.Errorf("failed to make script executable: %w", err)
+	}
+	return tmpfile.Name(), nil
+}
+
+// runScript writes script to a temp file, runs it in sb under cfg, and
+// cleans up the temp file afterwards.
+func runScript(ctx context.Context, sb sandbox.Sandbox, script string, cfg sandbox.Config) (sandbox.Result, error) {
+	path, err := writeScriptTempFile(script)
+	if err != nil {
+		return sandbox.Result{}, err
+	}
+	defer os.Remove(path)
+
+	return sb.Run(ctx, path, cfg)
+}
+
+// recordExecution saves an Execution row for a sandbox run. If scriptID is
+// nil (the script wasn't generated through /generate-script, or the caller
+// didn't pass one back), a minimal Script row is saved first so the
+// executions.script_id foreign key has somewhere to point. Errors are
+// logged rather than surfaced - a failure to record history shouldn't fail
+// the execution itself.
+func recordExecution(ctx context.Context, store *history.Store, scriptID *int64, script string, cfg sandbox.Config, started time.Time, result sandbox.Result) {
+	id := int64(0)
+	if scriptID != nil {
+		id = *scriptID
+	} else {
+		savedID, err := store.SaveScript(ctx, history.Script{
+			CreatedAt: started,
+			Script:    script,
+		})
 		if err != nil {
-			log.Printf("Error reading response: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response"})
+			log.Printf("Error saving ad-hoc script to history: %v", err)
 			return
 		}
+		id = savedID
+	}
+
+	cfgJSON, err := json.Marshal(cfg)
+	if err != nil {
+		log.Printf("Error encoding sandbox config for history: %v", err)
+		cfgJSON = []byte("{}")
+	}
+
+	if _, err := store.SaveExecution(ctx, history.Execution{
+		ScriptID:      id,
+		StartedAt:     started,
+		FinishedAt:    time.Now(),
+		ExitCode:      result.ExitCode,
+		Stdout:        result.Stdout,
+		Stderr:        result.Stderr,
+		SandboxConfig: string(cfgJSON),
+	}); err != nil {
+		log.Printf("Error saving execution to history: %v", err)
+	}
+}
 
-		// Log the raw response for debugging
-		log.Printf("Raw API Response: %s", string(body))
+func handleExecuteScript(store *history.Store) gin.HandlerFunc {
+	sb := sandbox.New()
+	log.Printf("Using %q sandbox backend for script execution", sb.Name())
 
-		if resp.StatusCode != http.StatusOK {
-			log.Printf("API error (status %d): %s", resp.StatusCode, string(body))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("API error: %s", string(body))})
+	return func(c *gin.Context) {
+		var req ExecuteScriptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Parse response
-		var anthropicResp AnthropicResponse
-		if err := json.Unmarshal(body, &anthropicResp); err != nil {
-			log.Printf("Error parsing response: %v\nResponse body: %s", err, string(body))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse response"})
+		log.Printf("Received script to execute: %s", req.Script)
+
+		if pattern := findDestructivePattern(req.Script); pattern != "" && !req.Confirm {
+			log.Printf("Refusing to execute script matching destructive pattern %q without Confirm", pattern)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": fmt.Sprintf("script matches a destructive pattern (%s); re-submit with \"confirm\": true to run it anyway", pattern),
+			})
 			return
 		}
 
-		if len(anthropicResp.Content) == 0 {
-			log.Printf("Empty response content from AI")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "No content in AI response"})
+		if findings := blockingLintFindings(req.Script, req); len(findings) > 0 {
+			log.Printf("Refusing to execute script with %d error-level lint finding(s)", len(findings))
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":    "script failed static analysis; re-submit with \"override\": true and \"override_reason\" set to run it anyway",
+				"findings": findings,
+			})
 			return
 		}
 
-		// Get the text content from the response
-		var scriptContent string
-		for _, content := range anthropicResp.Content {
-			if content.Type == "text" {
-				scriptContent = content.Text
-				break
+		cfg := sandbox.Config{
+			TimeoutSeconds: req.TimeoutSeconds,
+			MemoryMB:       req.MemoryMB,
+			AllowNetwork:   req.AllowNetwork,
+			Env:            req.Env,
+		}
+
+		started := time.Now()
+		result, err := runScript(c.Request.Context(), sb, req.Script, cfg)
+		if err != nil && !result.TimedOut {
+			log.Printf("Error executing script: %v\nStderr: %s", err, result.Stderr)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to run sandbox: %v", err)})
+			return
+		}
+		recordExecution(c.Request.Context(), store, req.ScriptID, req.Script, cfg, started, result)
+
+		resp := ExecuteScriptResponse{
+			Success:      err == nil && result.ExitCode == 0,
+			Output:       result.Stdout,
+			ExitCode:     result.ExitCode,
+			TimedOut:     result.TimedOut,
+			Killed:       result.Killed,
+			PeakMemoryKB: result.PeakMemoryKB,
+		}
+		if !resp.Success {
+			if result.TimedOut {
+				resp.Error = fmt.Sprintf("script timed out: %v", err)
+			} else {
+				resp.Error = fmt.Sprintf("script exited with code %d\n%s", result.ExitCode, result.Stderr)
 			}
 		}
 
-		if scriptContent == "" {
-			log.Printf("No text content found in response")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "No text content in AI response"})
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// handleGenerateScriptStream mirrors handleGenerateScript but forwards the
+// provider's response as it streams in, instead of waiting for the whole
+// script to be generated. Only providers implementing
+// provider.StreamingProvider support this (currently Anthropic and
+// Gemini); others return a 400 telling the caller to use /generate-script.
+func handleGenerateScriptStream(registry *provider.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ScriptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, ScriptResponse{
-			Script: scriptContent,
+		log.Printf("Received prompt for streaming (provider=%q): %s", req.Provider, req.Prompt)
+
+		p, err := registry.Get(req.Provider)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		streamer, ok := p.(provider.StreamingProvider)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("provider %q does not support streaming; use /generate-script instead", p.Name())})
+			return
+		}
+
+		ctx := c.Request.Context()
+		deltas := make(chan string)
+		streamErr := make(chan error, 1)
+
+		go func() {
+			defer close(deltas)
+			streamErr <- streamer.GenerateStream(ctx, scriptSystemPrompt, req.Prompt, provider.Opts{}, func(text string) {
+				// The consumer below stops reading as soon as ctx is done
+				// (client disconnect), so without this select a send here
+				// would block forever and leak this goroutine along with
+				// the upstream HTTP connection it's reading from.
+				select {
+				case deltas <- text:
+				case <-ctx.Done():
+				}
+			})
+		}()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case text, open := <-deltas:
+				if !open {
+					if err := <-streamErr; err != nil {
+						log.Printf("Error reading AI stream: %v", err)
+						c.SSEvent("error", err.Error())
+					} else {
+						c.SSEvent("done", "")
+					}
+					return false
+				}
+				c.SSEvent("delta", text)
+				return true
+			case <-ctx.Done():
+				log.Printf("Client disconnected from generate-script stream")
+				return false
+			}
 		})
 	}
 }
 
-func handleExecuteScript() gin.HandlerFunc {
+// handleExecuteScriptStream mirrors handleExecuteScript but forwards stdout
+// and stderr line-by-line as the script runs, with a final "exit" event
+// carrying the outcome. If the client disconnects mid-run, the sandboxed
+// process is killed via context cancellation.
+func handleExecuteScriptStream(store *history.Store) gin.HandlerFunc {
+	sb := sandbox.New()
+
 	return func(c *gin.Context) {
 		var req ExecuteScriptRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -201,55 +584,327 @@ func handleExecuteScript() gin.HandlerFunc {
 			return
 		}
 
-		log.Printf("Received script to execute: %s", req.Script)
+		log.Printf("Received script to execute (streaming): %s", req.Script)
 
-		// Create a temporary script file
-		tmpfile, err := ioutil.TempFile("", "script-*.sh")
+		if pattern := findDestructivePattern(req.Script); pattern != "" && !req.Confirm {
+			log.Printf("Refusing to execute script matching destructive pattern %q without Confirm", pattern)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": fmt.Sprintf("script matches a destructive pattern (%s); re-submit with \"confirm\": true to run it anyway", pattern),
+			})
+			return
+		}
+
+		if findings := blockingLintFindings(req.Script, req); len(findings) > 0 {
+			log.Printf("Refusing to execute script with %d error-level lint finding(s)", len(findings))
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":    "script failed static analysis; re-submit with \"override\": true and \"override_reason\" set to run it anyway",
+				"findings": findings,
+			})
+			return
+		}
+
+		path, err := writeScriptTempFile(req.Script)
 		if err != nil {
-			log.Printf("Error creating temp file: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create temporary script file"})
+			log.Printf("Error preparing script for execution: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		defer os.Remove(tmpfile.Name())
+		defer os.Remove(path)
+
+		cfg := sandbox.Config{
+			TimeoutSeconds: req.TimeoutSeconds,
+			MemoryMB:       req.MemoryMB,
+			AllowNetwork:   req.AllowNetwork,
+			Env:            req.Env,
+		}
+
+		type outputLine struct {
+			stream string
+			line   string
+		}
+
+		lines := make(chan outputLine, 64)
+		done := make(chan struct{})
+		var result sandbox.Result
+		var runErr error
+
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		started := time.Now()
+		go func() {
+			result, runErr = sb.Stream(ctx, path, cfg, func(stream, line string) {
+				lines <- outputLine{stream: stream, line: line}
+			})
+			close(lines)
+			close(done)
+		}()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case l, open := <-lines:
+				if !open {
+					<-done
+					recordExecution(c.Request.Context(), store, req.ScriptID, req.Script, cfg, started, result)
+					exit := gin.H{
+						"exit_code": result.ExitCode,
+						"timed_out": result.TimedOut,
+						"killed":    result.Killed,
+					}
+					if runErr != nil && !result.TimedOut {
+						exit["error"] = runErr.Error()
+					}
+					payload, _ := json.Marshal(exit)
+					c.SSEvent("exit", string(payload))
+					return false
+				}
+				c.SSEvent(l.stream, l.line)
+				return true
+			case <-c.Request.Context().Done():
+				log.Printf("Client disconnected from execute-script stream, killing sandboxed process")
+				cancel()
+				return false
+			}
+		})
+	}
+}
+
+// scriptListItem is the shape returned by GET /scripts and GET /scripts/:id
+// - a separate type rather than history.Script directly so the JSON field
+// names stay snake_case like the rest of the API.
+type scriptListItem struct {
+	ID        int64  `json:"id"`
+	CreatedAt string `json:"created_at"`
+	Prompt    string `json:"prompt"`
+	Provider  string `json:"provider"`
+	Model     string `json:"model,omitempty"`
+	Script    string `json:"script"`
+	ParentID  *int64 `json:"parent_id,omitempty"`
+}
+
+func toScriptListItem(sc history.Script) scriptListItem {
+	return scriptListItem{
+		ID:        sc.ID,
+		CreatedAt: sc.CreatedAt.Format(time.RFC3339),
+		Prompt:    sc.Prompt,
+		Provider:  sc.Provider,
+		Model:     sc.Model,
+		Script:    sc.Script,
+		ParentID:  sc.ParentID,
+	}
+}
 
-		// Write the script to the temp file
-		if _, err := tmpfile.Write([]byte(req.Script)); err != nil {
-			log.Printf("Error writing to temp file: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write script to file"})
+// parseIDParam parses the ":id" path parameter shared by the /scripts/:id
+// routes, writing a 400 response and returning ok=false on failure.
+func parseIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid script id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// handleListScripts handles GET /scripts?limit=&offset=&q=, returning a page
+// of previously generated scripts (most recent first).
+func handleListScripts(store *history.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		offset, _ := strconv.Atoi(c.Query("offset"))
+
+		scripts, total, err := store.ListScripts(c.Request.Context(), history.ListParams{
+			Limit:  limit,
+			Offset: offset,
+			Query:  c.Query("q"),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		if err := tmpfile.Close(); err != nil {
-			log.Printf("Error closing temp file: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close script file"})
+
+		items := make([]scriptListItem, len(scripts))
+		for i, sc := range scripts {
+			items[i] = toScriptListItem(sc)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"scripts": items,
+			"total":   total,
+			"offset":  offset,
+		})
+	}
+}
+
+// handleGetScript handles GET /scripts/:id.
+func handleGetScript(store *history.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c)
+		if !ok {
 			return
 		}
 
-		// Make the script executable
-		if err := os.Chmod(tmpfile.Name(), 0755); err != nil {
-			log.Printf("Error making script executable: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to make script executable"})
+		sc, err := store.GetScript(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, history.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Execute the script
-		cmd := exec.Command("bash", tmpfile.Name())
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
+		c.JSON(http.StatusOK, toScriptListItem(sc))
+	}
+}
+
+// handleListExecutions handles GET /scripts/:id/executions.
+func handleListExecutions(store *history.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c)
+		if !ok {
+			return
+		}
 
-		err = cmd.Run()
+		executions, err := store.ListExecutions(c.Request.Context(), id)
 		if err != nil {
-			log.Printf("Error executing script: %v\nStderr: %s", err, stderr.String())
-			c.JSON(http.StatusOK, ExecuteScriptResponse{
-				Success: false,
-				Error:   fmt.Sprintf("Error executing script: %v\n%s", err, stderr.String()),
-			})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, ExecuteScriptResponse{
-			Success: true,
-			Output:  stdout.String(),
+		c.JSON(http.StatusOK, gin.H{"executions": executions})
+	}
+}
+
+// handleRerunScript handles POST /scripts/:id/rerun, re-executing a
+// previously generated script through the sandbox and recording the result
+// as a new execution against the same script.
+func handleRerunScript(store *history.Store) gin.HandlerFunc {
+	sb := sandbox.New()
+
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c)
+		if !ok {
+			return
+		}
+
+		var req RerunScriptRequest
+		if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		sc, err := store.GetScript(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, history.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		cfg := sandbox.Config{
+			TimeoutSeconds: req.TimeoutSeconds,
+			MemoryMB:       req.MemoryMB,
+			AllowNetwork:   req.AllowNetwork,
+			Env:            req.Env,
+		}
+
+		started := time.Now()
+		result, err := runScript(c.Request.Context(), sb, sc.Script, cfg)
+		if err != nil && !result.TimedOut {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to run sandbox: %v", err)})
+			return
+		}
+		recordExecution(c.Request.Context(), store, &sc.ID, sc.Script, cfg, started, result)
+
+		resp := ExecuteScriptResponse{
+			Success:      err == nil && result.ExitCode == 0,
+			Output:       result.Stdout,
+			ExitCode:     result.ExitCode,
+			TimedOut:     result.TimedOut,
+			Killed:       result.Killed,
+			PeakMemoryKB: result.PeakMemoryKB,
+		}
+		if !resp.Success {
+			if result.TimedOut {
+				resp.Error = fmt.Sprintf("script timed out: %v", err)
+			} else {
+				resp.Error = fmt.Sprintf("script exited with code %d\n%s", result.ExitCode, result.Stderr)
+			}
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// refineSystemPrompt guides the LLM to revise an existing script rather than
+// write one from scratch, so it has a chance to preserve what's already
+// correct about it.
+const refineSystemPrompt = scriptSystemPrompt + `
+You are revising a script you previously wrote based on new instructions
+from the user. Keep what already works; change only what the new
+instructions require. Respond with the complete revised script.`
+
+// handleRefineScript handles POST /scripts/:id/refine, generating a new
+// script from a parent script plus refinement instructions and recording it
+// as that parent's child via ParentID.
+func handleRefineScript(registry *provider.Registry, store *history.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c)
+		if !ok {
+			return
+		}
+
+		var req RefineScriptRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		parent, err := store.GetScript(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, history.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		providerName := req.Provider
+		if providerName == "" {
+			providerName = parent.Provider
+		}
+		p, err := registry.Get(providerName)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userPrompt := fmt.Sprintf("Original script:\n%s\n\nRefinement instructions: %s", parent.Script, req.Prompt)
+		scriptContent, err := p.Generate(c.Request.Context(), refineSystemPrompt, userPrompt, provider.Opts{})
+		if err != nil {
+			log.Printf("Error refining script with %s: %v", p.Name(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		newID, err := store.SaveScript(c.Request.Context(), history.Script{
+			CreatedAt: time.Now(),
+			Prompt:    req.Prompt,
+			Provider:  p.Name(),
+			Model:     p.ResolvedModel(provider.Opts{}),
+			Script:    scriptContent,
+			ParentID:  &parent.ID,
+		})
+		if err != nil {
+			log.Printf("Error saving refined script to history: %v", err)
+		}
+
+		c.JSON(http.StatusOK, ScriptResponse{
+			ID:     newID,
+			Script: scriptContent,
 		})
 	}
 }
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// AnthropicProvider talks to the Anthropic Messages API.
+type AnthropicProvider struct {
+	APIKey      string
+	Model       string
+	Temperature float64
+	TopP        float64
+	MaxTokens   int
+}
+
+func (p *AnthropicProvider) Name() string { return "anthropic" }
+
+// ResolvedModel reports the model Generate would use for opts.
+func (p *AnthropicProvider) ResolvedModel(opts Opts) string {
+	return firstNonEmpty(opts.Model, p.Model, "claude-3-opus-20240229")
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	Messages    []anthropicMessage `json:"messages"`
+	System      string             `json:"system"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature,omitempty"`
+	TopP        float64            `json:"top_p,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+// anthropicStreamEvent is a single decoded SSE payload from the streaming
+// Messages API. Only the fields GenerateStream cares about are modeled.
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicResponse struct {
+	Id      string `json:"id"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Model string `json:"model"`
+	Role  string `json:"role"`
+}
+
+func (p *AnthropicProvider) Generate(ctx context.Context, systemPrompt, userPrompt string, opts Opts) (string, error) {
+	model := firstNonEmpty(opts.Model, p.Model, "claude-3-opus-20240229")
+	maxTokens := firstPositiveInt(opts.MaxTokens, p.MaxTokens, 4096)
+	temperature := firstPositiveFloat(opts.Temperature, p.Temperature, 1.0)
+	topP := firstPositiveFloat(opts.TopP, p.TopP, 1.0)
+
+	reqBody := anthropicRequest{
+		Model:       model,
+		Messages:    []anthropicMessage{{Role: "user", Content: userPrompt}},
+		System:      systemPrompt,
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+		TopP:        topP,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Anthropic request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Anthropic request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.APIKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to Anthropic: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Anthropic response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Anthropic API error: %s", string(body))
+	}
+
+	var anthropicResp anthropicResponse
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return "", fmt.Errorf("failed to parse Anthropic response: %w", err)
+	}
+
+	for _, content := range anthropicResp.Content {
+		if content.Type == "text" {
+			return content.Text, nil
+		}
+	}
+
+	return "", fmt.Errorf("no text content in Anthropic response")
+}
+
+// GenerateStream behaves like Generate but invokes onDelta with each chunk
+// of text as it streams in from the API, instead of waiting for the full
+// response. It implements StreamingProvider, which Provider doesn't require
+// of every backend.
+func (p *AnthropicProvider) GenerateStream(ctx context.Context, systemPrompt, userPrompt string, opts Opts, onDelta func(string)) error {
+	model := firstNonEmpty(opts.Model, p.Model, "claude-3-opus-20240229")
+	maxTokens := firstPositiveInt(opts.MaxTokens, p.MaxTokens, 4096)
+	temperature := firstPositiveFloat(opts.Temperature, p.Temperature, 1.0)
+	topP := firstPositiveFloat(opts.TopP, p.TopP, 1.0)
+
+	reqBody := anthropicRequest{
+		Model:       model,
+		Messages:    []anthropicMessage{{Role: "user", Content: userPrompt}},
+		System:      systemPrompt,
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+		TopP:        topP,
+		Stream:      true,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Anthropic request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create Anthropic request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.APIKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request to Anthropic: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Anthropic API error: %s", string(body))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			continue
+		}
+		if ev.Type == "content_block_delta" && ev.Delta.Text != "" {
+			onDelta(ev.Delta.Text)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// GeminiProvider talks to the Gemini generateContent API.
+type GeminiProvider struct {
+	APIKey      string
+	Model       string
+	Temperature float64
+	TopP        float64
+	MaxTokens   int
+}
+
+func (p *GeminiProvider) Name() string { return "gemini" }
+
+// ResolvedModel reports the model Generate would use for opts.
+func (p *GeminiProvider) ResolvedModel(opts Opts) string {
+	return firstNonEmpty(opts.Model, p.Model, "gemini-1.5-flash-latest")
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	Contents         []geminiContent `json:"contents"`
+	GenerationConfig struct {
+		Temperature     float64 `json:"temperature"`
+		TopK            int     `json:"topK"`
+		TopP            float64 `json:"topP"`
+		MaxOutputTokens int     `json:"maxOutputTokens"`
+	} `json:"generationConfig"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []geminiPart `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+}
+
+// buildRequest assembles the request body shared by Generate and
+// GenerateStream - they differ only in which endpoint they hit and how
+// they read the response.
+func (p *GeminiProvider) buildRequest(systemPrompt, userPrompt string, opts Opts) geminiRequest {
+	temperature := firstPositiveFloat(opts.Temperature, p.Temperature, 0.7)
+	topP := firstPositiveFloat(opts.TopP, p.TopP, 0.95)
+	maxTokens := firstPositiveInt(opts.MaxTokens, p.MaxTokens, 2048)
+
+	var req geminiRequest
+	req.Contents = []geminiContent{
+		{
+			Role:  "user",
+			Parts: []geminiPart{{Text: fmt.Sprintf("%s\n\n%s", systemPrompt, userPrompt)}},
+		},
+	}
+	req.GenerationConfig.Temperature = temperature
+	req.GenerationConfig.TopK = 40
+	req.GenerationConfig.TopP = topP
+	req.GenerationConfig.MaxOutputTokens = maxTokens
+	return req
+}
+
+func geminiErrorFromBody(body []byte) error {
+	var geminiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &geminiErr) == nil && geminiErr.Error.Message != "" {
+		return fmt.Errorf("Gemini API error: %s", geminiErr.Error.Message)
+	}
+	return fmt.Errorf("Gemini API error: %s", string(body))
+}
+
+func (p *GeminiProvider) Generate(ctx context.Context, systemPrompt, userPrompt string, opts Opts) (string, error) {
+	model := p.ResolvedModel(opts)
+	geminiReq := p.buildRequest(systemPrompt, userPrompt, opts)
+
+	jsonData, err := json.Marshal(geminiReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Gemini request: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", model, p.APIKey)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Gemini request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to Gemini: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Gemini response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", geminiErrorFromBody(body)
+	}
+
+	var geminiResp geminiResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		return "", fmt.Errorf("failed to parse Gemini response: %w", err)
+	}
+
+	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("empty response from Gemini")
+	}
+
+	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
+}
+
+// GenerateStream behaves like Generate but invokes onDelta with each chunk
+// of text as it streams in from Gemini's :streamGenerateContent endpoint
+// (requested with alt=sse so it reads like Anthropic's event stream),
+// instead of waiting for the full response.
+func (p *GeminiProvider) GenerateStream(ctx context.Context, systemPrompt, userPrompt string, opts Opts, onDelta func(string)) error {
+	model := p.ResolvedModel(opts)
+	geminiReq := p.buildRequest(systemPrompt, userPrompt, opts)
+
+	jsonData, err := json.Marshal(geminiReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Gemini request: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:streamGenerateContent?alt=sse&key=%s", model, p.APIKey)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create Gemini request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request to Gemini: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return geminiErrorFromBody(body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		if len(chunk.Candidates) > 0 && len(chunk.Candidates[0].Content.Parts) > 0 {
+			onDelta(chunk.Candidates[0].Content.Parts[0].Text)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// OllamaProvider talks to a local Ollama daemon, so users can generate
+// scripts with a model running entirely on their own machine.
+type OllamaProvider struct {
+	Host  string // e.g. http://localhost:11434
+	Model string
+}
+
+func (p *OllamaProvider) Name() string { return "ollama" }
+
+// ResolvedModel reports the model Generate would use for opts.
+func (p *OllamaProvider) ResolvedModel(opts Opts) string {
+	return firstNonEmpty(opts.Model, p.Model, "codellama")
+}
+
+type ollamaRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+type ollamaResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+}
+
+func (p *OllamaProvider) Generate(ctx context.Context, systemPrompt, userPrompt string, opts Opts) (string, error) {
+	model := firstNonEmpty(opts.Model, p.Model, "codellama")
+	host := firstNonEmpty(p.Host, "http://localhost:11434")
+
+	reqBody := ollamaRequest{
+		Model:  model,
+		Prompt: fmt.Sprintf("%s\n\n%s", systemPrompt, userPrompt),
+		Stream: false,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Ollama request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", host+"/api/generate", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Ollama request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach Ollama at %s (is it running?): %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Ollama response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Ollama API error: %s", string(body))
+	}
+
+	var ollamaResp ollamaResponse
+	if err := json.Unmarshal(body, &ollamaResp); err != nil {
+		return "", fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+
+	return ollamaResp.Response, nil
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// OpenAIProvider talks to the OpenAI chat/completions API.
+type OpenAIProvider struct {
+	APIKey      string
+	Model       string
+	Temperature float64
+	MaxTokens   int
+}
+
+func (p *OpenAIProvider) Name() string { return "openai" }
+
+// ResolvedModel reports the model Generate would use for opts.
+func (p *OpenAIProvider) ResolvedModel(opts Opts) string {
+	return firstNonEmpty(opts.Model, p.Model, "gpt-4o-mini")
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float64         `json:"temperature,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (p *OpenAIProvider) Generate(ctx context.Context, systemPrompt, userPrompt string, opts Opts) (string, error) {
+	model := firstNonEmpty(opts.Model, p.Model, "gpt-4o-mini")
+	temperature := firstPositiveFloat(opts.Temperature, p.Temperature, 0.7)
+	maxTokens := firstPositiveInt(opts.MaxTokens, p.MaxTokens, 4096)
+
+	reqBody := openAIRequest{
+		Model: model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal OpenAI request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create OpenAI request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to OpenAI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read OpenAI response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OpenAI API error: %s", string(body))
+	}
+
+	var openAIResp openAIResponse
+	if err := json.Unmarshal(body, &openAIResp); err != nil {
+		return "", fmt.Errorf("failed to parse OpenAI response: %w", err)
+	}
+
+	if len(openAIResp.Choices) == 0 {
+		return "", fmt.Errorf("empty response from OpenAI")
+	}
+
+	return openAIResp.Choices[0].Message.Content, nil
+}
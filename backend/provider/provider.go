@@ -0,0 +1,68 @@
+// Package provider abstracts over the different LLM backends
+// script-copilot can generate scripts with, so the server isn't hardcoded
+// to a single vendor.
+package provider
+
+import (
+	"context"
+	"fmt"
+)
+
+// Opts carries the generation parameters a caller wants applied, with
+// zero values meaning "use the provider's default".
+type Opts struct {
+	Model       string
+	Temperature float64
+	TopP        float64
+	MaxTokens   int
+}
+
+// Provider generates a shell script from a system/user prompt pair.
+type Provider interface {
+	Generate(ctx context.Context, systemPrompt, userPrompt string, opts Opts) (string, error)
+	Name() string
+
+	// ResolvedModel reports the model name Generate would use for opts,
+	// without making a request - so callers that need to record what was
+	// actually used (e.g. for history) don't have to duplicate each
+	// provider's model-selection fallback logic.
+	ResolvedModel(opts Opts) string
+}
+
+// StreamingProvider is implemented by providers that can stream a response
+// incrementally via GenerateStream instead of only returning the complete
+// script the way Generate does. Not every backend's API supports this, so
+// it's a separate, optional interface rather than part of Provider itself.
+type StreamingProvider interface {
+	Provider
+	GenerateStream(ctx context.Context, systemPrompt, userPrompt string, opts Opts, onDelta func(string)) error
+}
+
+// Registry holds the providers available to the server, keyed by name
+// ("anthropic", "gemini", "openai", "ollama").
+type Registry struct {
+	providers map[string]Provider
+	def       string
+}
+
+// NewRegistry builds a Registry from the given providers, defaulting
+// selection to defaultName when a request doesn't specify one.
+func NewRegistry(defaultName string, providers map[string]Provider) (*Registry, error) {
+	if _, ok := providers[defaultName]; !ok {
+		return nil, fmt.Errorf("default provider %q is not among the configured providers", defaultName)
+	}
+	return &Registry{providers: providers, def: defaultName}, nil
+}
+
+// Get returns the named provider, or the registry's default if name is
+// empty. It errors if the name doesn't match a configured provider.
+func (r *Registry) Get(name string) (Provider, error) {
+	if name == "" {
+		name = r.def
+	}
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+	return p, nil
+}
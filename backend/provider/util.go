@@ -0,0 +1,31 @@
+package provider
+
+// firstNonEmpty returns the first non-empty string in vals.
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// firstPositiveInt returns the first positive int in vals.
+func firstPositiveInt(vals ...int) int {
+	for _, v := range vals {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// firstPositiveFloat returns the first positive float64 in vals.
+func firstPositiveFloat(vals ...float64) float64 {
+	for _, v := range vals {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
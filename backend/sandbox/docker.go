@@ -0,0 +1,55 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// DockerSandbox runs the script inside a disposable container: read-only
+// root filesystem, no network by default, and hard CPU/memory/pid caps.
+type DockerSandbox struct {
+	Image string
+}
+
+func (s *DockerSandbox) Name() string { return "docker" }
+
+func (s *DockerSandbox) Run(ctx context.Context, scriptPath string, cfg Config) (Result, error) {
+	return runCommand(ctx, cfg, "docker", s.args(scriptPath, cfg)...)
+}
+
+func (s *DockerSandbox) Stream(ctx context.Context, scriptPath string, cfg Config, onLine LineFunc) (Result, error) {
+	return streamCommand(ctx, cfg, onLine, "docker", s.args(scriptPath, cfg)...)
+}
+
+func (s *DockerSandbox) args(scriptPath string, cfg Config) []string {
+	image := s.Image
+	if image == "" {
+		image = "bash:5"
+	}
+
+	mountDir := filepath.Dir(scriptPath)
+	scriptName := filepath.Base(scriptPath)
+
+	args := []string{
+		"run", "--rm",
+		"--user", "65534:65534", // nobody:nogroup, never root
+		"--read-only",
+		"--tmpfs", "/tmp:rw,noexec,nosuid,size=64m",
+		"--pids-limit", "64",
+		"--memory", fmt.Sprintf("%dm", cfg.memoryMB()),
+		"--cpus", "1",
+		"-v", fmt.Sprintf("%s:/work:ro", mountDir),
+		"-w", "/work",
+	}
+
+	if !cfg.AllowNetwork {
+		args = append(args, "--network=none")
+	}
+
+	for _, e := range cfg.Env {
+		args = append(args, "-e", e)
+	}
+
+	return append(args, image, "bash", scriptName)
+}
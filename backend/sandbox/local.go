@@ -0,0 +1,98 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// LocalSandbox runs the script directly on the host, but inside a dedicated
+// working directory and under the rlimits configured in Config. It does not
+// provide the filesystem or network isolation DockerSandbox gives you; use
+// it when Docker isn't available.
+//
+// Deliberately no chroot: a real chroot jail needs its own copy of bash,
+// coreutils, their shared libraries, and /dev nodes, rebuilt and kept in
+// sync with the host on every change - that's real filesystem isolation
+// surface to maintain for a fallback path whose whole point is to be the
+// lightweight option. DockerSandbox is the backend to reach for when that
+// level of isolation matters; LocalSandbox only promises rlimits.
+//
+// Deliberately ulimit, not syscall.Setrlimit: os/exec has no pre-exec hook,
+// so there's nowhere for a parent-process Setrlimit call to run between
+// fork and exec of the script. The wrapper shell's `ulimit` builtin calls
+// the same setrlimit(2) syscall, just from inside the child before it
+// execs the script - so it ends up equivalent without a helper binary.
+type LocalSandbox struct{}
+
+func (s *LocalSandbox) Name() string { return "local" }
+
+func (s *LocalSandbox) Run(ctx context.Context, scriptPath string, cfg Config) (Result, error) {
+	wrapped, env, cleanup, err := s.prepare(scriptPath, cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	defer cleanup()
+
+	return runCommand(ctx, Config{TimeoutSeconds: cfg.TimeoutSeconds, Env: env}, "bash", "-c", wrapped)
+}
+
+func (s *LocalSandbox) Stream(ctx context.Context, scriptPath string, cfg Config, onLine LineFunc) (Result, error) {
+	wrapped, env, cleanup, err := s.prepare(scriptPath, cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	defer cleanup()
+
+	return streamCommand(ctx, Config{TimeoutSeconds: cfg.TimeoutSeconds, Env: env}, onLine, "bash", "-c", wrapped)
+}
+
+// prepare sets up a dedicated working directory and builds the shell
+// wrapper that applies rlimits before exec'ing the script. Callers must run
+// the returned cleanup func once the command has finished.
+func (s *LocalSandbox) prepare(scriptPath string, cfg Config) (wrapped string, env []string, cleanup func(), err error) {
+	workDir, err := os.MkdirTemp("", "script-copilot-sandbox-*")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to create sandbox working directory: %w", err)
+	}
+
+	// Wrap the script invocation in a subshell that sets rlimits before
+	// exec'ing it, so the limits apply to the script process and anything
+	// it forks. CPU is in seconds, address space and file size in KB.
+	wrapped = fmt.Sprintf(
+		"ulimit -t %d; ulimit -v %d; ulimit -n 256; ulimit -f %d; cd %q && exec bash %q",
+		cfg.timeout()/1e9, // ulimit -t wants whole seconds
+		cfg.memoryMB()*1024,
+		512*1024, // cap output files at 512MB
+		workDir,
+		scriptPath,
+	)
+
+	// Start from a curated environment, not the server's actual one: to
+	// os/exec, cmd.Env == nil means "inherit everything", and the server's
+	// environment holds ANTHROPIC_API_KEY/OPENAI_API_KEY/GEMINI_API_KEY and
+	// API_KEYS. PATH is the only thing the script needs from the host to
+	// find bash and coreutils; this applies regardless of AllowNetwork -
+	// toggling network access is a different axis from leaking secrets.
+	env = append([]string{"PATH=" + basePATH()}, cfg.Env...)
+	if !cfg.AllowNetwork {
+		// Best-effort network containment: scripts that shell out to curl
+		// etc. still reach the network on a LocalSandbox, the only hard
+		// guarantee of AllowNetwork=false comes from DockerSandbox's
+		// --network=none. Record the intent via env so script authors and
+		// downstream tooling can at least detect it.
+		env = append(env, "SANDBOX_NETWORK=disabled")
+	}
+
+	return wrapped, env, func() { os.RemoveAll(workDir) }, nil
+}
+
+// basePATH is the PATH handed to sandboxed scripts - the host's own PATH if
+// set, otherwise a standard fallback, so `bash`/coreutils can still be found
+// without inheriting the rest of the server's environment.
+func basePATH() string {
+	if p := os.Getenv("PATH"); p != "" {
+		return p
+	}
+	return "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+}
@@ -0,0 +1,207 @@
+// Package sandbox provides pluggable backends for running untrusted shell
+// scripts with resource limits and timeouts instead of shelling out directly
+// on the host.
+package sandbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Config describes the resource limits and policy to apply to a single
+// script run. Zero values fall back to the defaults below.
+type Config struct {
+	TimeoutSeconds int
+	MemoryMB       int
+	AllowNetwork   bool
+	Env            []string
+}
+
+const (
+	DefaultTimeoutSeconds = 30
+	DefaultMemoryMB       = 256
+)
+
+func (c Config) timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return DefaultTimeoutSeconds * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+func (c Config) memoryMB() int {
+	if c.MemoryMB <= 0 {
+		return DefaultMemoryMB
+	}
+	return c.MemoryMB
+}
+
+// Result is the outcome of running a script through a Sandbox.
+type Result struct {
+	Stdout       string
+	Stderr       string
+	ExitCode     int
+	TimedOut     bool
+	Killed       bool
+	PeakMemoryKB int64
+}
+
+// LineFunc receives one line of output as it's produced. stream is either
+// "stdout" or "stderr".
+type LineFunc func(stream, line string)
+
+// Sandbox runs a script file in isolation and reports what happened.
+type Sandbox interface {
+	// Run executes the script at scriptPath and blocks until it finishes,
+	// the context is cancelled, or the configured timeout elapses.
+	Run(ctx context.Context, scriptPath string, cfg Config) (Result, error)
+
+	// Stream behaves like Run but invokes onLine as each line of output is
+	// produced, for callers that want to forward output incrementally
+	// (e.g. over SSE) instead of waiting for the script to finish.
+	Stream(ctx context.Context, scriptPath string, cfg Config, onLine LineFunc) (Result, error)
+
+	Name() string
+}
+
+// New picks a Sandbox implementation based on the SANDBOX_BACKEND env var
+// ("docker" or "local"), defaulting to LocalSandbox since Docker may not be
+// available on every host running this server.
+func New() Sandbox {
+	switch os.Getenv("SANDBOX_BACKEND") {
+	case "docker":
+		return &DockerSandbox{Image: dockerImage()}
+	default:
+		return &LocalSandbox{}
+	}
+}
+
+func dockerImage() string {
+	if img := os.Getenv("SANDBOX_DOCKER_IMAGE"); img != "" {
+		return img
+	}
+	return "bash:5"
+}
+
+// peakMemoryKB reads the peak resident set size, in KB, that the OS
+// recorded for the finished process. It returns 0 if state is nil (the
+// process never started) or the platform doesn't expose rusage this way.
+func peakMemoryKB(state *os.ProcessState) int64 {
+	if state == nil {
+		return 0
+	}
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return ru.Maxrss
+	}
+	return 0
+}
+
+func runCommand(ctx context.Context, cfg Config, name string, args ...string) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = cfg.Env
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	res := Result{
+		Stdout:       stdout.String(),
+		Stderr:       stderr.String(),
+		PeakMemoryKB: peakMemoryKB(cmd.ProcessState),
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		res.TimedOut = true
+		res.Killed = true
+		return res, fmt.Errorf("script timed out after %s", cfg.timeout())
+	}
+
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			res.ExitCode = exitErr.ExitCode()
+			return res, nil
+		}
+		return res, err
+	}
+
+	return res, nil
+}
+
+// streamCommand is runCommand's line-by-line sibling: it invokes onLine as
+// output arrives instead of buffering it all until the command exits.
+func streamCommand(ctx context.Context, cfg Config, onLine LineFunc, name string, args ...string) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = cfg.Env
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to attach stdout: %w", err)
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to attach stderr: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return Result{}, fmt.Errorf("failed to start script: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go collectLines(&wg, stdoutPipe, &stdout, "stdout", onLine)
+	go collectLines(&wg, stderrPipe, &stderr, "stderr", onLine)
+	wg.Wait()
+	runErr := cmd.Wait()
+
+	res := Result{
+		Stdout:       stdout.String(),
+		Stderr:       stderr.String(),
+		PeakMemoryKB: peakMemoryKB(cmd.ProcessState),
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		res.TimedOut = true
+		res.Killed = true
+		return res, fmt.Errorf("script timed out after %s", cfg.timeout())
+	}
+
+	if runErr != nil {
+		if exitErr, ok := runErr.(*exec.ExitError); ok {
+			res.ExitCode = exitErr.ExitCode()
+			return res, nil
+		}
+		return res, runErr
+	}
+
+	return res, nil
+}
+
+func collectLines(wg *sync.WaitGroup, r io.Reader, buf *bytes.Buffer, stream string, onLine LineFunc) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if onLine != nil {
+			onLine(stream, line)
+		}
+	}
+}